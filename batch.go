@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	youtube "github.com/kkdai/youtube/v2"
+
+	"github.com/synzen/ytaudio/namer"
+)
+
+// BatchOptions configures a playlist/channel batch download run.
+type BatchOptions struct {
+	PlaylistID   string
+	ChannelID    string
+	Concurrency  int
+	MaxDuration  time.Duration
+	MaxSize      int64
+	NameTemplate string
+	LedgerPath   string
+	TagFormat    string
+	Peaks        int
+}
+
+// batchVideo is one video discovered via the YouTube Data API, enough to
+// filter and name it before it is queued for download.
+type batchVideo struct {
+	ID           string
+	Title        string
+	ChannelTitle string
+	PlaylistName string
+	Index        int
+	PublishedAt  string
+}
+
+// playlistItemsResponse mirrors the subset of the playlistItems.list
+// response this package needs.
+type playlistItemsResponse struct {
+	NextPageToken string
+	Items         []struct {
+		Snippet struct {
+			Title        string
+			ChannelTitle string
+			PublishedAt  string
+			ResourceId   struct {
+				VideoId string
+			}
+		}
+	}
+}
+
+// searchListResponse mirrors the subset of the search.list response used to
+// enumerate a channel's uploads ordered by date.
+type searchListResponse struct {
+	NextPageToken string
+	Items         []struct {
+		Id struct {
+			VideoId string
+		}
+		Snippet struct {
+			Title        string
+			ChannelTitle string
+			PublishedAt  string
+		}
+	}
+}
+
+// listPlaylistVideos enumerates every video in playlistID, paginating
+// through nextPageToken.
+func listPlaylistVideos(playlistID string) ([]batchVideo, error) {
+	var videos []batchVideo
+	pageToken := ""
+	for {
+		urlStr := "https://www.googleapis.com/youtube/v3/playlistItems?part=snippet&maxResults=50&playlistId=" +
+			url.QueryEscape(playlistID) + "&key=" + ApiKey
+		if pageToken != "" {
+			urlStr += "&pageToken=" + pageToken
+		}
+		body, err := getResponseBody(urlStr)
+		if err != nil {
+			return nil, err
+		}
+		var page playlistItemsResponse
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			videos = append(videos, batchVideo{
+				ID:           item.Snippet.ResourceId.VideoId,
+				Title:        item.Snippet.Title,
+				ChannelTitle: item.Snippet.ChannelTitle,
+				PlaylistName: playlistID,
+				Index:        len(videos) + 1,
+				PublishedAt:  item.Snippet.PublishedAt,
+			})
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+	return videos, nil
+}
+
+// listChannelVideos enumerates every video uploaded by channelID, newest
+// first, paginating through nextPageToken.
+func listChannelVideos(channelID string) ([]batchVideo, error) {
+	var videos []batchVideo
+	pageToken := ""
+	for {
+		urlStr := "https://www.googleapis.com/youtube/v3/search?part=snippet&maxResults=50&type=video&order=date&channelId=" +
+			url.QueryEscape(channelID) + "&key=" + ApiKey
+		if pageToken != "" {
+			urlStr += "&pageToken=" + pageToken
+		}
+		body, err := getResponseBody(urlStr)
+		if err != nil {
+			return nil, err
+		}
+		var page searchListResponse
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, err
+		}
+		for _, item := range page.Items {
+			videos = append(videos, batchVideo{
+				ID:           item.Id.VideoId,
+				Title:        item.Snippet.Title,
+				ChannelTitle: item.Snippet.ChannelTitle,
+				Index:        len(videos) + 1,
+				PublishedAt:  item.Snippet.PublishedAt,
+			})
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+	return videos, nil
+}
+
+// runBatch enumerates the playlist or channel in opts and downloads every
+// video that passes the duration/size filters and isn't already in the
+// ledger, using a worker pool of opts.Concurrency goroutines.
+func runBatch(opts BatchOptions) error {
+	var videos []batchVideo
+	var err error
+	if opts.PlaylistID != "" {
+		videos, err = listPlaylistVideos(opts.PlaylistID)
+	} else {
+		videos, err = listChannelVideos(opts.ChannelID)
+	}
+	if err != nil {
+		return fmt.Errorf("enumerating videos: %w", err)
+	}
+
+	l, err := loadLedger(opts.LedgerPath)
+	if err != nil {
+		return fmt.Errorf("loading ledger: %w", err)
+	}
+
+	n := namer.New(opts.NameTemplate)
+
+	jobs := make(chan batchVideo)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for v := range jobs {
+				if err := downloadBatchVideo(v, opts, n, l); err != nil {
+					log.Printf("%s: %v", v.ID, err)
+				}
+			}
+		}()
+	}
+
+	for _, v := range videos {
+		if l.Has(v.ID) {
+			continue
+		}
+		jobs <- v
+	}
+	close(jobs)
+	wg.Wait()
+	return nil
+}
+
+func downloadBatchVideo(v batchVideo, opts BatchOptions, n *namer.Namer, l *ledger) error {
+	client := youtube.Client{}
+	video, err := client.GetVideo(v.ID)
+	if err != nil {
+		return fmt.Errorf("fetching video info: %w", err)
+	}
+
+	if isLikelyLivestream(video) {
+		log.Printf("skipping %s: likely an in-progress livestream (zero/unknown duration)", v.ID)
+		return nil
+	}
+	if opts.MaxDuration > 0 && video.Duration > opts.MaxDuration {
+		log.Printf("skipping %s: duration %s exceeds max %s", v.ID, video.Duration, opts.MaxDuration)
+		return nil
+	}
+
+	selectedFormat, ok := bestAudioFormat(video.Formats)
+	if !ok {
+		return fmt.Errorf("no audio formats available")
+	}
+	if opts.MaxSize > 0 && selectedFormat.ContentLength > opts.MaxSize {
+		log.Printf("skipping %s: size %d exceeds max %d", v.ID, selectedFormat.ContentLength, opts.MaxSize)
+		return nil
+	}
+	ext := extensionForMimeType(selectedFormat.MimeType)
+
+	outputPath := n.Name(namer.Vars{
+		Channel:  v.ChannelTitle,
+		Playlist: v.PlaylistName,
+		Index:    v.Index,
+		Title:    v.Title,
+		Ext:      ext,
+	})
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+
+	downloader := NewDownloader()
+	downloadErr := downloader.Download(context.Background(), v.ID, selectedFormat.ItagNo, ext, file, nil)
+	file.Close()
+	if downloadErr != nil {
+		return downloadErr
+	}
+
+	meta := TrackMetadata{
+		VideoID:       v.ID,
+		Title:         video.Title,
+		ChannelTitle:  v.ChannelTitle,
+		PlaylistTitle: v.PlaylistName,
+		Description:   video.Description,
+		PublishedAt:   parsePublishedAt(v.PublishedAt),
+	}
+	if err := tagOutput(outputPath, opts.TagFormat, meta); err != nil {
+		log.Printf("tagging %s: %v", v.ID, err)
+	}
+
+	if opts.Peaks > 0 {
+		if err := writePeaksSidecar(outputPath, opts.Peaks); err != nil {
+			log.Printf("generating peaks for %s: %v", v.ID, err)
+		}
+	}
+
+	return l.MarkDone(v.ID)
+}
+
+// isLikelyLivestream reports whether video is probably still broadcasting
+// live rather than a finished upload. kkdai/youtube reports Duration as
+// zero (or a partial, still-growing value) for an in-progress stream, so
+// MaxDuration alone never catches one; a video with no usable duration is
+// treated as live and dropped.
+func isLikelyLivestream(video *youtube.Video) bool {
+	return video.Duration <= 0
+}
+
+// parsePublishedAt parses the Data API's RFC 3339 snippet.publishedAt,
+// returning the zero time if it's missing or malformed.
+func parsePublishedAt(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	youtube "github.com/kkdai/youtube/v2"
+)
+
+func TestIsLikelyLivestream(t *testing.T) {
+	cases := []struct {
+		name     string
+		duration time.Duration
+		want     bool
+	}{
+		{"zero duration", 0, true},
+		{"finished upload", 5 * time.Minute, false},
+	}
+	for _, c := range cases {
+		video := &youtube.Video{Duration: c.duration}
+		if got := isLikelyLivestream(video); got != c.want {
+			t.Errorf("%s: isLikelyLivestream(%s) = %v, want %v", c.name, c.duration, got, c.want)
+		}
+	}
+}
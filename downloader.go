@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	youtube "github.com/kkdai/youtube/v2"
+)
+
+// progressReader wraps an io.Reader and reports bytes transferred plus an
+// ETA (based on the total size, if known) on Progress as it is read.
+type progressReader struct {
+	r        io.Reader
+	total    int64
+	read     int64
+	started  time.Time
+	Progress chan DownloadProgress
+}
+
+// DownloadProgress is a single progress update emitted while streaming a
+// video's audio.
+type DownloadProgress struct {
+	BytesRead int64
+	Total     int64
+	ETA       time.Duration
+}
+
+func newProgressReader(r io.Reader, total int64) *progressReader {
+	return &progressReader{
+		r:        r,
+		total:    total,
+		started:  time.Now(),
+		Progress: make(chan DownloadProgress, 1),
+	}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+
+	var eta time.Duration
+	if p.total > 0 && p.read > 0 {
+		elapsed := time.Since(p.started)
+		rate := float64(p.read) / elapsed.Seconds()
+		if rate > 0 {
+			remaining := float64(p.total - p.read)
+			eta = time.Duration(remaining/rate) * time.Second
+		}
+	}
+
+	select {
+	case p.Progress <- DownloadProgress{BytesRead: p.read, Total: p.total, ETA: eta}:
+	default:
+	}
+
+	if err == io.EOF {
+		close(p.Progress)
+	}
+	return n, err
+}
+
+// Downloader streams a video's audio directly from YouTube into an ffmpeg
+// process for transcoding, without ever writing the source container to
+// disk. It is shared by the interactive CLI and the HTTP job worker.
+type Downloader struct {
+	client youtube.Client
+}
+
+// NewDownloader returns a ready-to-use Downloader.
+func NewDownloader() *Downloader {
+	return &Downloader{}
+}
+
+// Download streams videoID's audio in the given itag's format through
+// ffmpeg, transcoding it to the extension implied by format, and writes the
+// result to out. progress, if non-nil, receives updates as the source
+// stream is read.
+func (d *Downloader) Download(ctx context.Context, videoID string, itag int, format string, out io.Writer, progress chan<- DownloadProgress) error {
+	video, err := d.client.GetVideoContext(ctx, videoID)
+	if err != nil {
+		return fmt.Errorf("fetching video %s: %w", videoID, err)
+	}
+
+	var stream *youtube.Format
+	for i, f := range video.Formats {
+		if f.ItagNo == itag {
+			stream = &video.Formats[i]
+			break
+		}
+	}
+	if stream == nil {
+		return fmt.Errorf("itag %d not found for video %s", itag, videoID)
+	}
+
+	body, size, err := d.client.GetStreamContext(ctx, video, stream)
+	if err != nil {
+		return fmt.Errorf("opening stream for video %s: %w", videoID, err)
+	}
+	defer body.Close()
+
+	pr := newProgressReader(body, size)
+	if progress != nil {
+		go func() {
+			for update := range pr.Progress {
+				progress <- update
+			}
+		}()
+	}
+
+	return d.Transcode(ctx, pr, format, out)
+}
+
+// Transcode pipes r through ffmpeg into the muxer implied by format and
+// writes the result to out. Download uses this internally once it has its
+// own source stream open; it is also exposed so other sources of a raw
+// audio stream (e.g. the extractors) can feed ffmpeg directly instead of
+// writing a temporary file and shelling out a second time.
+func (d *Downloader) Transcode(ctx context.Context, r io.Reader, format string, out io.Writer) error {
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-i", "pipe:0", "-vn", "-f", ffmpegMuxer(format), "pipe:1")
+	cmd.Stdin = r
+	cmd.Stdout = out
+	return cmd.Run()
+}
+
+// bestAudioFormat picks the highest-bitrate audio-only format from a
+// kkdai/youtube format list, used wherever we need format/size metadata
+// without pulling in rylio/ytdl's separate, incompatible metadata API.
+func bestAudioFormat(formats []youtube.Format) (youtube.Format, bool) {
+	var best youtube.Format
+	found := false
+	for _, f := range formats {
+		if f.AudioChannels == 0 {
+			continue
+		}
+		if !found || f.Bitrate > best.Bitrate {
+			best = f
+			found = true
+		}
+	}
+	return best, found
+}
+
+// ffmpegMuxer maps a container file extension (as produced by
+// extensionForMimeType) to the ffmpeg muxer name that writes that container,
+// since the two don't always match ("m4a" is an extension, not a muxer).
+func ffmpegMuxer(extension string) string {
+	switch extension {
+	case "m4a":
+		return "ipod"
+	default:
+		return extension
+	}
+}
+
+// extensionForMimeType maps a kkdai/youtube format's MIME type to the file
+// extension its container implies.
+func extensionForMimeType(mimeType string) string {
+	switch {
+	case strings.HasPrefix(mimeType, "audio/webm"):
+		return "webm"
+	case strings.HasPrefix(mimeType, "audio/mp4"):
+		return "m4a"
+	default:
+		return "audio"
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFfmpegMuxer(t *testing.T) {
+	cases := map[string]string{
+		"m4a":  "ipod",
+		"webm": "webm",
+		"ogg":  "ogg",
+		"":     "",
+	}
+	for extension, want := range cases {
+		if got := ffmpegMuxer(extension); got != want {
+			t.Errorf("ffmpegMuxer(%q) = %q, want %q", extension, got, want)
+		}
+	}
+}
+
+func TestProgressReaderReportsBytesAndTotal(t *testing.T) {
+	data := bytes.Repeat([]byte{0xFF}, 100)
+	pr := newProgressReader(bytes.NewReader(data), int64(len(data)))
+
+	buf := make([]byte, 40)
+	n, err := pr.Read(buf)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	update := <-pr.Progress
+	if update.BytesRead != int64(n) {
+		t.Errorf("BytesRead = %d, want %d", update.BytesRead, n)
+	}
+	if update.Total != int64(len(data)) {
+		t.Errorf("Total = %d, want %d", update.Total, len(data))
+	}
+}
+
+func TestProgressReaderClosesProgressOnEOF(t *testing.T) {
+	pr := newProgressReader(bytes.NewReader(nil), 0)
+	if _, err := pr.Read(make([]byte, 1)); err == nil {
+		t.Fatal("Read on empty reader should return an error")
+	}
+	for range pr.Progress {
+		// drain any buffered update sent before the channel was closed
+	}
+}
@@ -0,0 +1,148 @@
+// Package bandcamp implements extractors.Extractor against Bandcamp, which
+// has no official API: search uses the site's public autocomplete endpoint
+// and stream resolution scrapes the "trackinfo" JSON embedded in a track
+// page.
+package bandcamp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+
+	"github.com/synzen/ytaudio/extractors"
+)
+
+func init() {
+	extractors.Register("bandcamp", &Extractor{})
+}
+
+// Extractor is the Bandcamp extractors.Extractor implementation. Results
+// and Formats use the track's page URL as their ID, since Bandcamp has no
+// stable numeric ID scheme exposed publicly.
+type Extractor struct{}
+
+type autocompleteResponse struct {
+	Auto struct {
+		Results []struct {
+			Name        string `json:"name"`
+			BandName    string `json:"band_name"`
+			ItemURLRoot string `json:"item_url_root"`
+			ItemURLPath string `json:"item_url_path"`
+		} `json:"results"`
+	} `json:"auto"`
+}
+
+// Search queries Bandcamp's public autocomplete endpoint.
+func (e *Extractor) Search(query string) ([]extractors.Result, error) {
+	resp, err := http.PostForm("https://bandcamp.com/api/bcsearch_public_api/1/autocomplete_elastic", map[string][]string{
+		"search_text": {query},
+		"fan_id":      {""},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bandcamp autocomplete: non-200 status %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var parsed autocompleteResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, err
+	}
+
+	results := make([]extractors.Result, 0, len(parsed.Auto.Results))
+	for _, item := range parsed.Auto.Results {
+		if item.ItemURLPath == "" {
+			continue
+		}
+		results = append(results, extractors.Result{
+			ID:           item.ItemURLPath,
+			Title:        item.Name,
+			ChannelTitle: item.BandName,
+		})
+	}
+	return results, nil
+}
+
+// trackInfoPattern extracts the trackinfo JSON array Bandcamp embeds in
+// every track page's HTML.
+var trackInfoPattern = regexp.MustCompile(`trackinfo\s*:\s*(\[.*?\]),\n`)
+
+type trackInfo struct {
+	File map[string]string `json:"file"`
+}
+
+func (e *Extractor) fetchTrackInfo(pageURL string) (*trackInfo, error) {
+	resp, err := http.Get(pageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	match := trackInfoPattern.FindSubmatch(body)
+	if match == nil {
+		return nil, fmt.Errorf("no trackinfo found on %s", pageURL)
+	}
+	var tracks []trackInfo
+	if err := json.Unmarshal(match[1], &tracks); err != nil {
+		return nil, err
+	}
+	if len(tracks) == 0 {
+		return nil, fmt.Errorf("empty trackinfo on %s", pageURL)
+	}
+	return &tracks[0], nil
+}
+
+// GetStreams returns the single mp3-128 stream Bandcamp's free tier exposes.
+func (e *Extractor) GetStreams(id string) ([]extractors.Format, error) {
+	info, err := e.fetchTrackInfo(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := info.File["mp3-128"]; !ok {
+		return nil, fmt.Errorf("no mp3-128 stream found for %s", id)
+	}
+	return []extractors.Format{{ID: "mp3-128", Extension: "mp3", AudioBitrate: 128}}, nil
+}
+
+// Download streams the chosen format's URL directly into w.
+func (e *Extractor) Download(ctx context.Context, id string, format extractors.Format, w io.Writer) error {
+	info, err := e.fetchTrackInfo(id)
+	if err != nil {
+		return err
+	}
+	streamURL, ok := info.File[format.ID]
+	if !ok {
+		return fmt.Errorf("stream %q not found for %s", format.ID, id)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, streamURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bandcamp stream: non-200 status %d", resp.StatusCode)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
@@ -0,0 +1,68 @@
+// Package extractors defines the pluggable interface each audio source
+// (YouTube, SoundCloud, Bandcamp, a direct URL, ...) implements, along with
+// a registry extractors dropped under extractors/<name>/ add themselves to
+// from an init() func. This mirrors the annie project's extractor registry.
+package extractors
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+// Result is a single search hit, enough to list and then resolve streams
+// for.
+type Result struct {
+	ID           string
+	Title        string
+	ChannelTitle string
+}
+
+// Format is one downloadable rendition of a Result.
+type Format struct {
+	ID           string
+	Extension    string
+	AudioBitrate int
+}
+
+// Extractor is implemented by each audio source.
+type Extractor interface {
+	// Search looks up query and returns matching Results. The "url"
+	// extractor skips search entirely and should return an error if called.
+	Search(query string) ([]Result, error)
+	// GetStreams lists the downloadable Formats for id.
+	GetStreams(id string) ([]Format, error)
+	// Download streams format of id into w.
+	Download(ctx context.Context, id string, format Format, w io.Writer) error
+}
+
+var registry = make(map[string]Extractor)
+
+// Register adds an Extractor under name. Called from the extractor
+// subpackage's init().
+func Register(name string, e Extractor) {
+	registry[name] = e
+}
+
+// Get looks up a previously Registered Extractor by name.
+func Get(name string) (Extractor, bool) {
+	e, ok := registry[name]
+	return e, ok
+}
+
+// DetectSource guesses which registered extractor should handle input,
+// based on its URL, falling back to "youtube" for a bare search query.
+func DetectSource(input string) string {
+	switch {
+	case strings.Contains(input, "soundcloud.com"):
+		return "soundcloud"
+	case strings.Contains(input, "bandcamp.com"):
+		return "bandcamp"
+	case strings.Contains(input, "youtube.com"), strings.Contains(input, "youtu.be"):
+		return "youtube"
+	case strings.HasPrefix(input, "http://"), strings.HasPrefix(input, "https://"):
+		return "url"
+	default:
+		return "youtube"
+	}
+}
@@ -0,0 +1,153 @@
+// Package soundcloud implements extractors.Extractor against SoundCloud's
+// public client_id-based API, resolving HLS manifests for download.
+package soundcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/synzen/ytaudio/extractors"
+)
+
+const apiBase = "https://api-v2.soundcloud.com"
+
+// Extractor is the SoundCloud extractors.Extractor implementation. ClientID
+// defaults to the SOUNDCLOUD_CLIENT_ID env var if left empty.
+type Extractor struct {
+	ClientID string
+}
+
+func init() {
+	extractors.Register("soundcloud", &Extractor{ClientID: os.Getenv("SOUNDCLOUD_CLIENT_ID")})
+}
+
+type searchResponse struct {
+	Collection []track `json:"collection"`
+}
+
+type track struct {
+	ID       int64  `json:"id"`
+	Title    string `json:"title"`
+	User     struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Media struct {
+		Transcodings []struct {
+			URL    string `json:"url"`
+			Format struct {
+				Protocol string `json:"protocol"`
+				MimeType string `json:"mime_type"`
+			} `json:"format"`
+		} `json:"transcodings"`
+	} `json:"media"`
+}
+
+func (e *Extractor) get(path string) ([]byte, error) {
+	resp, err := http.Get(apiBase + path + "&client_id=" + e.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("soundcloud api: non-200 status %d", resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Search queries SoundCloud's /search/tracks endpoint.
+func (e *Extractor) Search(query string) ([]extractors.Result, error) {
+	body, err := e.get("/search/tracks?q=" + url.QueryEscape(query) + "&limit=10")
+	if err != nil {
+		return nil, err
+	}
+	var parsed searchResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, err
+	}
+
+	results := make([]extractors.Result, 0, len(parsed.Collection))
+	for _, t := range parsed.Collection {
+		results = append(results, extractors.Result{
+			ID:           strconv.FormatInt(t.ID, 10),
+			Title:        t.Title,
+			ChannelTitle: t.User.Username,
+		})
+	}
+	return results, nil
+}
+
+// GetStreams resolves the HLS/progressive transcodings SoundCloud exposes
+// for the track.
+func (e *Extractor) GetStreams(id string) ([]extractors.Format, error) {
+	body, err := e.get("/tracks/" + url.PathEscape(id) + "?")
+	if err != nil {
+		return nil, err
+	}
+	var t track
+	if err := json.Unmarshal(body, &t); err != nil {
+		return nil, err
+	}
+
+	formats := make([]extractors.Format, len(t.Media.Transcodings))
+	for i := range t.Media.Transcodings {
+		formats[i] = extractors.Format{
+			ID:        strconv.Itoa(i),
+			Extension: "mp3",
+		}
+	}
+	return formats, nil
+}
+
+// Download resolves format's transcoding's signed progressive/HLS URL and
+// pipes it through ffmpeg (HLS needs demuxing) into w.
+func (e *Extractor) Download(ctx context.Context, id string, format extractors.Format, w io.Writer) error {
+	body, err := e.get("/tracks/" + url.PathEscape(id) + "?")
+	if err != nil {
+		return err
+	}
+	var t track
+	if err := json.Unmarshal(body, &t); err != nil {
+		return err
+	}
+
+	index, err := strconv.Atoi(format.ID)
+	if err != nil || index < 0 || index >= len(t.Media.Transcodings) {
+		return fmt.Errorf("invalid format id %q for track %s", format.ID, id)
+	}
+	transcoding := t.Media.Transcodings[index]
+
+	resolved, err := e.resolveStreamURL(transcoding.URL)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-i", resolved, "-f", "mp3", "-vn", "pipe:1")
+	cmd.Stdout = w
+	return cmd.Run()
+}
+
+// resolveStreamURL follows SoundCloud's indirection from a transcoding
+// metadata URL to the actual signed HLS/progressive stream URL.
+func (e *Extractor) resolveStreamURL(transcodingURL string) (string, error) {
+	resp, err := http.Get(transcodingURL + "?client_id=" + e.ClientID)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var parsed struct {
+		URL string `json:"url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", err
+	}
+	return parsed.URL, nil
+}
@@ -0,0 +1,54 @@
+// Package urlextractor implements extractors.Extractor for any direct
+// media URL, skipping search entirely.
+package urlextractor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/synzen/ytaudio/extractors"
+)
+
+func init() {
+	extractors.Register("url", &Extractor{})
+}
+
+// Extractor streams whatever is at the given URL as-is.
+type Extractor struct{}
+
+// Search is unsupported: a direct URL is already the selection.
+func (e *Extractor) Search(query string) ([]extractors.Result, error) {
+	return nil, fmt.Errorf("the url extractor does not support search; pass the URL directly")
+}
+
+// GetStreams returns a single Format inferred from the URL's extension.
+func (e *Extractor) GetStreams(id string) ([]extractors.Format, error) {
+	ext := strings.TrimPrefix(path.Ext(id), ".")
+	if ext == "" {
+		ext = "bin"
+	}
+	return []extractors.Format{{ID: id, Extension: ext}}, nil
+}
+
+// Download streams the URL's body directly into w.
+func (e *Extractor) Download(ctx context.Context, id string, format extractors.Format, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, id, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching %s: non-200 status %d", id, resp.StatusCode)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
@@ -0,0 +1,142 @@
+// Package youtube implements extractors.Extractor against YouTube, using
+// the Data API for search and kkdai/youtube for stream resolution and
+// download.
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	kkyoutube "github.com/kkdai/youtube/v2"
+
+	"github.com/synzen/ytaudio/extractors"
+)
+
+// Extractor is the YouTube extractors.Extractor implementation. APIKey
+// defaults to the YOUTUBE_API_KEY env var if left empty.
+type Extractor struct {
+	APIKey string
+	client kkyoutube.Client
+}
+
+func init() {
+	extractors.Register("youtube", &Extractor{APIKey: os.Getenv("YOUTUBE_API_KEY")})
+}
+
+type searchResponse struct {
+	Items []struct {
+		ID struct {
+			VideoID string `json:"videoId"`
+		} `json:"id"`
+		Snippet struct {
+			Title        string `json:"title"`
+			ChannelTitle string `json:"channelTitle"`
+		} `json:"snippet"`
+	} `json:"items"`
+}
+
+// Search queries the YouTube Data API's search.list endpoint.
+func (e *Extractor) Search(query string) ([]extractors.Result, error) {
+	urlStr := "https://www.googleapis.com/youtube/v3/search?part=snippet&maxResults=10&type=video&q=" +
+		url.QueryEscape(query) + "&key=" + e.APIKey
+
+	resp, err := http.Get(urlStr)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("youtube search: non-200 status %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var parsed searchResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, err
+	}
+
+	results := make([]extractors.Result, 0, len(parsed.Items))
+	for _, item := range parsed.Items {
+		results = append(results, extractors.Result{
+			ID:           item.ID.VideoID,
+			Title:        item.Snippet.Title,
+			ChannelTitle: item.Snippet.ChannelTitle,
+		})
+	}
+	return results, nil
+}
+
+// GetStreams lists the audio-capable formats kkdai/youtube resolves for id.
+func (e *Extractor) GetStreams(id string) ([]extractors.Format, error) {
+	video, err := e.client.GetVideo(id)
+	if err != nil {
+		return nil, fmt.Errorf("fetching video %s: %w", id, err)
+	}
+
+	var formats []extractors.Format
+	for _, f := range video.Formats {
+		if f.AudioChannels == 0 {
+			continue
+		}
+		formats = append(formats, extractors.Format{
+			ID:           strconv.Itoa(f.ItagNo),
+			Extension:    extensionForMimeType(f.MimeType),
+			AudioBitrate: f.Bitrate,
+		})
+	}
+	return formats, nil
+}
+
+// Download streams id's chosen format directly from YouTube into w.
+func (e *Extractor) Download(ctx context.Context, id string, format extractors.Format, w io.Writer) error {
+	video, err := e.client.GetVideoContext(ctx, id)
+	if err != nil {
+		return fmt.Errorf("fetching video %s: %w", id, err)
+	}
+
+	itag, err := strconv.Atoi(format.ID)
+	if err != nil {
+		return fmt.Errorf("invalid format id %q: %w", format.ID, err)
+	}
+
+	var stream *kkyoutube.Format
+	for i, f := range video.Formats {
+		if f.ItagNo == itag {
+			stream = &video.Formats[i]
+			break
+		}
+	}
+	if stream == nil {
+		return fmt.Errorf("itag %d not found for video %s", itag, id)
+	}
+
+	body, _, err := e.client.GetStreamContext(ctx, video, stream)
+	if err != nil {
+		return fmt.Errorf("opening stream for video %s: %w", id, err)
+	}
+	defer body.Close()
+
+	_, err = io.Copy(w, body)
+	return err
+}
+
+func extensionForMimeType(mimeType string) string {
+	switch {
+	case len(mimeType) >= 10 && mimeType[:10] == "audio/webm":
+		return "webm"
+	case len(mimeType) >= 9 && mimeType[:9] == "audio/mp4":
+		return "m4a"
+	default:
+		return "audio"
+	}
+}
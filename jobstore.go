@@ -0,0 +1,138 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const jobStoreSchema = `
+CREATE TABLE IF NOT EXISTS jobs (
+	id          TEXT PRIMARY KEY,
+	video_id    TEXT NOT NULL,
+	format_itag INTEGER NOT NULL,
+	status      TEXT NOT NULL,
+	progress    REAL NOT NULL DEFAULT 0,
+	error       TEXT,
+	output_path TEXT,
+	s3_key      TEXT,
+	created_at  DATETIME NOT NULL,
+	updated_at  DATETIME NOT NULL
+);
+`
+
+// JobStatus enumerates the lifecycle states of a download Job.
+type JobStatus string
+
+const (
+	JobStatusQueued      JobStatus = "queued"
+	JobStatusDownloading JobStatus = "downloading"
+	JobStatusDone        JobStatus = "done"
+	JobStatusFailed      JobStatus = "failed"
+)
+
+// Job is a single queued or in-flight download, persisted so clients can
+// disconnect and resume polling for its progress later.
+type Job struct {
+	ID         string
+	VideoID    string
+	FormatItag int
+	Status     JobStatus
+	Progress   float64
+	Error      string
+	OutputPath string
+	S3Key      string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// JobStore persists Jobs to a SQLite database so the HTTP server can survive
+// restarts without losing track of in-progress downloads.
+type JobStore struct {
+	db *sql.DB
+}
+
+// NewJobStore opens (creating if necessary) a SQLite database at path and
+// runs its migrations.
+func NewJobStore(path string) (*JobStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(jobStoreSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("running job store migrations: %w", err)
+	}
+	return &JobStore{db: db}, nil
+}
+
+func (s *JobStore) Close() error {
+	return s.db.Close()
+}
+
+// Create inserts a new queued Job.
+func (s *JobStore) Create(job *Job) error {
+	now := time.Now()
+	job.Status = JobStatusQueued
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	_, err := s.db.Exec(
+		`INSERT INTO jobs (id, video_id, format_itag, status, progress, error, output_path, s3_key, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		job.ID, job.VideoID, job.FormatItag, job.Status, job.Progress, job.Error, job.OutputPath, job.S3Key, job.CreatedAt, job.UpdatedAt,
+	)
+	return err
+}
+
+// Get fetches a Job by ID.
+func (s *JobStore) Get(id string) (*Job, error) {
+	row := s.db.QueryRow(
+		`SELECT id, video_id, format_itag, status, progress, error, output_path, s3_key, created_at, updated_at
+		 FROM jobs WHERE id = ?`, id,
+	)
+	var job Job
+	var s3Key sql.NullString
+	if err := row.Scan(&job.ID, &job.VideoID, &job.FormatItag, &job.Status, &job.Progress, &job.Error, &job.OutputPath, &s3Key, &job.CreatedAt, &job.UpdatedAt); err != nil {
+		return nil, err
+	}
+	job.S3Key = s3Key.String
+	return &job, nil
+}
+
+// Update persists the current state of job, refreshing its updated_at.
+func (s *JobStore) Update(job *Job) error {
+	job.UpdatedAt = time.Now()
+	_, err := s.db.Exec(
+		`UPDATE jobs SET status = ?, progress = ?, error = ?, output_path = ?, s3_key = ?, updated_at = ? WHERE id = ?`,
+		job.Status, job.Progress, job.Error, job.OutputPath, job.S3Key, job.UpdatedAt, job.ID,
+	)
+	return err
+}
+
+// ListNonTerminal returns every queued or downloading Job, so the server can
+// requeue in-flight work after a restart.
+func (s *JobStore) ListNonTerminal() ([]*Job, error) {
+	rows, err := s.db.Query(
+		`SELECT id, video_id, format_itag, status, progress, error, output_path, s3_key, created_at, updated_at
+		 FROM jobs WHERE status IN (?, ?)`,
+		JobStatusQueued, JobStatusDownloading,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		var job Job
+		var s3Key sql.NullString
+		if err := rows.Scan(&job.ID, &job.VideoID, &job.FormatItag, &job.Status, &job.Progress, &job.Error, &job.OutputPath, &s3Key, &job.CreatedAt, &job.UpdatedAt); err != nil {
+			return nil, err
+		}
+		job.S3Key = s3Key.String
+		jobs = append(jobs, &job)
+	}
+	return jobs, rows.Err()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// ledger tracks video IDs that have already been downloaded by batch mode so
+// reruns can skip them. It is a flat JSON file rather than the server's
+// SQLite JobStore since batch runs are a one-shot CLI process, not a
+// long-lived service. Safe for concurrent use by multiple download workers.
+type ledger struct {
+	path string
+
+	mu   sync.Mutex
+	done map[string]bool
+}
+
+func loadLedger(path string) (*ledger, error) {
+	l := &ledger{path: path, done: make(map[string]bool)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		l.done[id] = true
+	}
+	return l, nil
+}
+
+func (l *ledger) Has(videoID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.done[videoID]
+}
+
+func (l *ledger) MarkDone(videoID string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.done[videoID] = true
+
+	ids := make([]string, 0, len(l.done))
+	for id := range l.done {
+		ids = append(ids, id)
+	}
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(l.path, data, 0644)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestLedgerMarkDoneAndHas(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	l, err := loadLedger(path)
+	if err != nil {
+		t.Fatalf("loadLedger: %v", err)
+	}
+
+	if l.Has("abc") {
+		t.Fatal("Has(abc) = true before MarkDone")
+	}
+	if err := l.MarkDone("abc"); err != nil {
+		t.Fatalf("MarkDone: %v", err)
+	}
+	if !l.Has("abc") {
+		t.Fatal("Has(abc) = false after MarkDone")
+	}
+
+	reloaded, err := loadLedger(path)
+	if err != nil {
+		t.Fatalf("loadLedger (reload): %v", err)
+	}
+	if !reloaded.Has("abc") {
+		t.Fatal("reloaded ledger doesn't have abc persisted")
+	}
+}
+
+func TestLedgerConcurrentMarkDone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	l, err := loadLedger(path)
+	if err != nil {
+		t.Fatalf("loadLedger: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := l.MarkDone(string(rune('a' + i%26))); err != nil {
+				t.Errorf("MarkDone: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
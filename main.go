@@ -2,15 +2,16 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
-	"os/exec"
 	"strconv"
 	"strings"
 
@@ -27,6 +28,7 @@ type SearchItemSnippet struct {
 	Title        string
 	Description  string
 	ChannelTitle string
+	PublishedAt  string
 }
 
 type SearchItemID struct {
@@ -113,6 +115,68 @@ func searchVideos(ids []string) (result VideoResult, err error) {
 
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	serverAddr := flag.String("server", "", "run an HTTP server on this address instead of the interactive CLI (e.g. :8080)")
+	dbPath := flag.String("db", "ytaudio.db", "path to the SQLite job store used by -server")
+	playlistID := flag.String("playlist", "", "download every video in this playlist ID")
+	channelID := flag.String("channel", "", "download every video uploaded by this channel ID, newest first")
+	concurrency := flag.Int("concurrency", 4, "number of videos to download concurrently in -playlist/-channel mode")
+	maxDuration := flag.Duration("max-duration", 0, "skip videos longer than this in -playlist/-channel mode (0 = no limit)")
+	maxSize := flag.Int64("max-size", 0, "skip videos whose audio format exceeds this many bytes in -playlist/-channel mode (0 = no limit)")
+	nameTemplate := flag.String("name-template", "{channel}/{playlist}/{index:03d} - {title}.{ext}", "filename template for -playlist/-channel mode")
+	ledgerPath := flag.String("ledger", "ytaudio-ledger.json", "path to the JSON ledger of already-downloaded video IDs")
+	tagFormat := flag.String("tag-format", "id3", "metadata format to embed in the output file: \"id3\" or \"vorbis\"")
+	peaks := flag.Int("peaks", 0, "generate a <basename>.peaks.json waveform sidecar with this many peaks (0 = disabled)")
+	source := flag.String("source", "", "extractor to use: \"youtube\", \"soundcloud\", \"bandcamp\", or \"url\" (unset runs the legacy YouTube Data API interactive flow below instead)")
+	flag.Parse()
+
+	if *serverAddr != "" {
+		if err := runServer(*serverAddr, *dbPath); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *playlistID != "" || *channelID != "" {
+		if len(ApiKey) == 0 {
+			fmt.Print("Enter API Key: ")
+			reader := bufio.NewReader(os.Stdin)
+			apiKey, err := reader.ReadString('\n')
+			if err != nil {
+				log.Fatal(err)
+			}
+			ApiKey = strings.TrimSpace(apiKey)
+		}
+		err := runBatch(BatchOptions{
+			PlaylistID:   *playlistID,
+			ChannelID:    *channelID,
+			Concurrency:  *concurrency,
+			MaxDuration:  *maxDuration,
+			MaxSize:      *maxSize,
+			NameTemplate: *nameTemplate,
+			LedgerPath:   *ledgerPath,
+			TagFormat:    *tagFormat,
+			Peaks:        *peaks,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *source != "" {
+		fmt.Print("Enter search query or URL: ")
+		reader := bufio.NewReader(os.Stdin)
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := runWithExtractor(*source, strings.TrimSpace(input), *tagFormat, *peaks); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	reader := bufio.NewReader(os.Stdin)
 	if len(ApiKey) == 0 {
 		fmt.Print("Enter API Key: ")
@@ -213,14 +277,14 @@ func main() {
 	}
 
 	fmt.Println("Fetching info...")
-	vid, err := ytdl.GetVideoInfoFromID(searchResult.Items[selection-1].ID.VideoID)
+	vid, err := ytdl.DefaultClient.GetVideoInfoFromID(context.Background(), searchResult.Items[selection-1].ID.VideoID)
 	if err != nil {
 		fmt.Println("Failed to get video info")
 		return
 	}
 
 	consoleOutput = "\n--AUDIO FORMATS--\n\n"
-	var audioFormats []ytdl.Format
+	var audioFormats []*ytdl.Format
 	bestFormat := vid.Formats.Best("audbr")[0]
 	for _, format := range vid.Formats {
 		if len(format.AudioEncoding) > 0 {
@@ -237,7 +301,7 @@ func main() {
 	fmt.Print("Select a audio format by typing the nubmer, or type \"best\" for the best audio, \"worst\" for the worst audio, or \"fastest\" to download the full video and then convert to mp3 audio with ffmpeg (fastest method): ")
 
 	var formatSelection int
-	var selectedFormat ytdl.Format
+	var selectedFormat *ytdl.Format
 
 	for {
 		if input, err := reader.ReadString('\n'); err == nil {
@@ -267,43 +331,48 @@ func main() {
 		selectedFormat = audioFormats[formatSelection]
 	}
 
+	outputFormat := selectedFormat.Extension
+	if formatSelection == -2 {
+		outputFormat = "mp3"
+	}
+
 	baseFileName := sanitize.BaseName(vid.Title)
-	fullfileName := baseFileName + "." + selectedFormat.Extension
+	fullfileName := baseFileName + "." + outputFormat
 	file, err := os.Create(fullfileName)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer file.Close()
-
-	cmd := exec.Command("ytdl", "-f", "itag:"+strconv.Itoa(selectedFormat.Itag), "-o", fullfileName, vid.ID)
-	cmd.Stdin = os.Stdin
-	cmd.Stderr = os.Stderr
-	cmd.Stdout = os.Stdout
-	runErr := cmd.Run()
-	if runErr != nil {
-		log.Fatal(err)
-	}
-	if formatSelection == -2 {
-		bitrate := "192"
-		if selectedFormat.AudioBitrate != 0 {
-			bitrate = strconv.Itoa(selectedFormat.AudioBitrate)
-		}
 
-		fmt.Printf("\nConverting to mp3 with bitrate %sk... \n\n", bitrate)
-		cmd := exec.Command("ffmpeg", "-i", fullfileName, "-f", "mp3", "-b:a", bitrate+"k", "-vn", baseFileName+".mp3")
-		cmd.Stdin = os.Stdin
-		cmd.Stderr = os.Stderr
-		cmd.Stdout = os.Stdout
-		runErr := cmd.Run()
-		if runErr != nil {
-			log.Fatal(err)
-		}
-		if err = os.Remove(fullfileName); err != nil {
-			log.Fatal(err)
+	progress := make(chan DownloadProgress)
+	go func() {
+		for update := range progress {
+			fmt.Printf("\r%d/%d bytes (ETA %s)   ", update.BytesRead, update.Total, update.ETA.Round(1e9))
 		}
+	}()
 
-	} else {
-		fmt.Println("Done")
+	downloader := NewDownloader()
+	downloadErr := downloader.Download(context.Background(), vid.ID, selectedFormat.Itag.Number, outputFormat, file, progress)
+	file.Close()
+	if downloadErr != nil {
+		log.Fatal(downloadErr)
+	}
+
+	meta := TrackMetadata{
+		VideoID:      vid.ID,
+		Title:        vid.Title,
+		ChannelTitle: vid.Uploader,
+		Description:  vid.Description,
+		PublishedAt:  parsePublishedAt(searchResult.Items[selection-1].Snippet.PublishedAt),
+	}
+	if err := tagOutput(fullfileName, *tagFormat, meta); err != nil {
+		log.Println("tagging output:", err)
+	}
+
+	if *peaks > 0 {
+		if err := writePeaksSidecar(fullfileName, *peaks); err != nil {
+			log.Println("generating peaks:", err)
+		}
 	}
 
+	fmt.Println("\nDone")
 }
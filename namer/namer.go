@@ -0,0 +1,93 @@
+// Package namer generates collision-free output filenames for batch
+// downloads from a template like "{channel}/{playlist}/{index:03d} - {title}.{ext}".
+package namer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kennygrant/sanitize"
+)
+
+// fieldPattern matches a template field, optionally with a printf-style
+// width spec such as "{index:03d}".
+var fieldPattern = regexp.MustCompile(`\{(\w+)(?::(\d+)d)?\}`)
+
+// Vars holds the values substituted into a filename template.
+type Vars struct {
+	Channel  string
+	Playlist string
+	Index    int
+	Title    string
+	Ext      string
+}
+
+// Namer renders filename templates and keeps track of names it has already
+// handed out so repeated titles don't collide on disk. Safe for concurrent
+// use by multiple download workers.
+type Namer struct {
+	template string
+
+	mu   sync.Mutex
+	seen map[string]int
+}
+
+// New returns a Namer for the given template, e.g.
+// "{channel}/{playlist}/{index:03d} - {title}.{ext}".
+func New(template string) *Namer {
+	return &Namer{
+		template: template,
+		seen:     make(map[string]int),
+	}
+}
+
+// Name renders the template with v, sanitizing free-text fields and
+// disambiguating a repeat with a "(2)", "(3)", ... suffix before the
+// extension.
+func (n *Namer) Name(v Vars) string {
+	rendered := fieldPattern.ReplaceAllStringFunc(n.template, func(match string) string {
+		groups := fieldPattern.FindStringSubmatch(match)
+		field, width := groups[1], groups[2]
+
+		var value string
+		switch field {
+		case "channel":
+			value = sanitize.BaseName(v.Channel)
+		case "playlist":
+			value = sanitize.BaseName(v.Playlist)
+		case "title":
+			value = sanitize.BaseName(v.Title)
+		case "ext":
+			value = v.Ext
+		case "index":
+			if width != "" {
+				pad, _ := strconv.Atoi(width)
+				value = fmt.Sprintf("%0*d", pad, v.Index)
+			} else {
+				value = strconv.Itoa(v.Index)
+			}
+		default:
+			value = match
+		}
+		return value
+	})
+
+	n.mu.Lock()
+	n.seen[rendered]++
+	count := n.seen[rendered]
+	n.mu.Unlock()
+
+	if count > 1 {
+		ext := ""
+		base := rendered
+		if dot := strings.LastIndex(rendered, "."); dot != -1 {
+			ext = rendered[dot:]
+			base = rendered[:dot]
+		}
+		rendered = fmt.Sprintf("%s (%d)%s", base, count, ext)
+	}
+	return rendered
+}
@@ -0,0 +1,39 @@
+package namer
+
+import "testing"
+
+func TestName(t *testing.T) {
+	n := New("{channel}/{playlist}/{index:03d} - {title}.{ext}")
+	got := n.Name(Vars{Channel: "My Channel", Playlist: "Some List", Index: 7, Title: "A Song", Ext: "mp3"})
+	want := "My-Channel/Some-List/007 - A-Song.mp3"
+	if got != want {
+		t.Errorf("Name() = %q, want %q", got, want)
+	}
+}
+
+func TestNameDeduplicatesRepeats(t *testing.T) {
+	n := New("{title}.{ext}")
+	v := Vars{Title: "Same Title", Ext: "mp3"}
+
+	first := n.Name(v)
+	second := n.Name(v)
+	third := n.Name(v)
+
+	if first != "Same-Title.mp3" {
+		t.Errorf("first Name() = %q, want %q", first, "Same-Title.mp3")
+	}
+	if second != "Same-Title (2).mp3" {
+		t.Errorf("second Name() = %q, want %q", second, "Same-Title (2).mp3")
+	}
+	if third != "Same-Title (3).mp3" {
+		t.Errorf("third Name() = %q, want %q", third, "Same-Title (3).mp3")
+	}
+}
+
+func TestNameSanitizesFreeTextFields(t *testing.T) {
+	n := New("{title}.{ext}")
+	got := n.Name(Vars{Title: "a/b:c", Ext: "mp3"})
+	if got == "a/b:c.mp3" {
+		t.Errorf("Name() did not sanitize path-unsafe characters, got %q", got)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const peaksSampleRate = 48000
+
+// Peaks is the JSON sidecar written alongside a downloaded file describing
+// its waveform, for building a visual preview without decoding the audio.
+type Peaks struct {
+	SampleRate int     `json:"sample_rate"`
+	Peaks      []int16 `json:"peaks"`
+}
+
+// generatePeaks decodes sourcePath to mono 16-bit PCM at 48kHz via ffmpeg
+// and buckets it into n equally-spaced peak amplitudes.
+func generatePeaks(sourcePath string, n int) (Peaks, error) {
+	cmd := exec.Command("ffmpeg", "-i", sourcePath, "-f", "s16le", "-ac", "1", "-ar", fmt.Sprintf("%d", peaksSampleRate), "-")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return Peaks{}, err
+	}
+	if err := cmd.Start(); err != nil {
+		return Peaks{}, err
+	}
+
+	samples, err := decodeSamples(stdout)
+	if err != nil {
+		cmd.Wait()
+		return Peaks{}, err
+	}
+	if err := cmd.Wait(); err != nil {
+		return Peaks{}, fmt.Errorf("decoding %s to pcm: %w", sourcePath, err)
+	}
+
+	return Peaks{
+		SampleRate: peaksSampleRate,
+		Peaks:      bucketPeaks(samples, n),
+	}, nil
+}
+
+func decodeSamples(r io.Reader) ([]int16, error) {
+	buffered := bufio.NewReader(r)
+	var samples []int16
+	for {
+		var sample int16
+		if err := binary.Read(buffered, binary.LittleEndian, &sample); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+// bucketPeaks splits samples into n equally-spaced windows and takes the
+// max absolute amplitude of each as that window's peak.
+func bucketPeaks(samples []int16, n int) []int16 {
+	if n <= 0 || len(samples) == 0 {
+		return nil
+	}
+	windowSize := len(samples) / n
+	if windowSize == 0 {
+		windowSize = 1
+	}
+
+	peaks := make([]int16, 0, n)
+	for start := 0; start < len(samples) && len(peaks) < n; start += windowSize {
+		end := start + windowSize
+		if end > len(samples) {
+			end = len(samples)
+		}
+		var max int16
+		for _, sample := range samples[start:end] {
+			if abs := absInt16(sample); abs > max {
+				max = abs
+			}
+		}
+		peaks = append(peaks, max)
+	}
+	return peaks
+}
+
+func absInt16(v int16) int16 {
+	if v < 0 {
+		if v == math.MinInt16 {
+			return math.MaxInt16
+		}
+		return -v
+	}
+	return v
+}
+
+// writePeaksSidecar runs generatePeaks on sourcePath and writes the result
+// to "<basename>.peaks.json".
+func writePeaksSidecar(sourcePath string, n int) error {
+	peaks, err := generatePeaks(sourcePath, n)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(peaks)
+	if err != nil {
+		return err
+	}
+
+	sidecarPath := strings.TrimSuffix(sourcePath, extensionOf(sourcePath)) + ".peaks.json"
+	return os.WriteFile(sidecarPath, data, 0644)
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestAbsInt16(t *testing.T) {
+	cases := map[int16]int16{
+		0:      0,
+		5:      5,
+		-5:     5,
+		32767:  32767,
+		-32768: 32767, // math.MinInt16 has no positive counterpart
+	}
+	for in, want := range cases {
+		if got := absInt16(in); got != want {
+			t.Errorf("absInt16(%d) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestBucketPeaks(t *testing.T) {
+	samples := []int16{1, -2, 3, -8, 5, 6, -1, 0}
+	peaks := bucketPeaks(samples, 4)
+	want := []int16{2, 8, 6, 1}
+	if len(peaks) != len(want) {
+		t.Fatalf("bucketPeaks returned %d peaks, want %d", len(peaks), len(want))
+	}
+	for i := range want {
+		if peaks[i] != want[i] {
+			t.Errorf("peaks[%d] = %d, want %d", i, peaks[i], want[i])
+		}
+	}
+}
+
+func TestBucketPeaksEmptyOrZeroN(t *testing.T) {
+	if got := bucketPeaks(nil, 4); got != nil {
+		t.Errorf("bucketPeaks(nil, 4) = %v, want nil", got)
+	}
+	if got := bucketPeaks([]int16{1, 2, 3}, 0); got != nil {
+		t.Errorf("bucketPeaks(samples, 0) = %v, want nil", got)
+	}
+}
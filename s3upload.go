@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// presignExpiry is how long a presigned GET URL returned by presignS3URL
+// stays valid.
+const presignExpiry = 15 * time.Minute
+
+// s3UploadEnabled reports whether the S3_BUCKET env var has been set,
+// which is how the server decides to stream output to S3 instead of disk.
+func s3UploadEnabled() bool {
+	return os.Getenv("S3_BUCKET") != ""
+}
+
+// uploadToS3 streams r to the configured bucket under key and returns the
+// object's URL. The AWS region and credentials are resolved the normal
+// aws-sdk-go-v2 way (env vars, shared config, instance role, etc).
+func uploadToS3(ctx context.Context, key string, r io.Reader) (string, error) {
+	bucket := os.Getenv("S3_BUCKET")
+	if bucket == "" {
+		return "", fmt.Errorf("S3_BUCKET is not set")
+	}
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return "", fmt.Errorf("loading AWS config: %w", err)
+	}
+	client := s3.NewFromConfig(cfg)
+	uploader := manager.NewUploader(client)
+	_, err = uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   r,
+	})
+	if err != nil {
+		return "", fmt.Errorf("uploading %s to s3: %w", key, err)
+	}
+	return fmt.Sprintf("s3://%s/%s", bucket, key), nil
+}
+
+// presignS3URL returns a presigned, time-limited HTTPS URL for GETting key
+// out of the configured bucket, so the server can hand it to clients instead
+// of proxying the object itself.
+func presignS3URL(ctx context.Context, key string) (string, error) {
+	bucket := os.Getenv("S3_BUCKET")
+	if bucket == "" {
+		return "", fmt.Errorf("S3_BUCKET is not set")
+	}
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return "", fmt.Errorf("loading AWS config: %w", err)
+	}
+	client := s3.NewFromConfig(cfg)
+	presignClient := s3.NewPresignClient(client)
+	req, err := presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(presignExpiry))
+	if err != nil {
+		return "", fmt.Errorf("presigning %s: %w", key, err)
+	}
+	return req.URL, nil
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kennygrant/sanitize"
+	"github.com/rylio/ytdl"
+)
+
+// Server exposes search/select/download over a REST API, backing downloads
+// with a persistent JobStore so clients can reconnect and keep polling.
+type Server struct {
+	store   *JobStore
+	jobs    chan *Job
+	workers int
+}
+
+// NewServer wires up a Server with the given JobStore and a worker pool of
+// the given size.
+func NewServer(store *JobStore, workers int) *Server {
+	s := &Server{
+		store:   store,
+		jobs:    make(chan *Job, 64),
+		workers: workers,
+	}
+	for i := 0; i < workers; i++ {
+		go s.worker()
+	}
+	return s
+}
+
+func (s *Server) worker() {
+	for job := range s.jobs {
+		s.runJob(job)
+	}
+}
+
+func (s *Server) runJob(job *Job) {
+	job.Status = JobStatusDownloading
+	if err := s.store.Update(job); err != nil {
+		log.Println("updating job:", err)
+	}
+
+	var err error
+	if s3UploadEnabled() {
+		job.S3Key, err = uploadJobToS3(context.Background(), job.ID, job.VideoID, job.FormatItag)
+	} else {
+		job.OutputPath, err = downloadForJob(job.VideoID, job.FormatItag)
+	}
+	if err != nil {
+		job.Status = JobStatusFailed
+		job.Error = err.Error()
+		if updateErr := s.store.Update(job); updateErr != nil {
+			log.Println("updating failed job:", updateErr)
+		}
+		return
+	}
+
+	job.Progress = 1
+	job.Status = JobStatusDone
+	if err := s.store.Update(job); err != nil {
+		log.Println("updating job:", err)
+	}
+}
+
+// handleSearch handles POST /search with a JSON body of {"query": "..."}.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Query string `json:"query"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, err := search(body.Query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleCreateJob handles POST /jobs with a JSON body of
+// {"video_id": "...", "format_itag": ...} and queues an asynchronous download.
+func (s *Server) handleCreateJob(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		VideoID    string `json:"video_id"`
+		FormatItag int    `json:"format_itag"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	job := &Job{
+		ID:         uuid.NewString(),
+		VideoID:    body.VideoID,
+		FormatItag: body.FormatItag,
+	}
+	if err := s.store.Create(job); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.jobs <- job
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// handleGetJob handles GET /jobs/:id, returning the job's current progress.
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request, id string) {
+	job, err := s.store.Get(id)
+	if err != nil {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(job)
+}
+
+// handleGetJobAudio handles GET /jobs/:id/audio, serving the finished file
+// once the job is done.
+func (s *Server) handleGetJobAudio(w http.ResponseWriter, r *http.Request, id string) {
+	job, err := s.store.Get(id)
+	if err != nil {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+	if job.Status != JobStatusDone {
+		http.Error(w, fmt.Sprintf("job is %s, not done", job.Status), http.StatusConflict)
+		return
+	}
+
+	if job.S3Key != "" {
+		url, err := presignS3URL(r.Context(), job.S3Key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, url, http.StatusFound)
+		return
+	}
+	http.ServeFile(w, r, job.OutputPath)
+}
+
+// ServeHTTP dispatches requests to the job/search handlers.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/search":
+		s.handleSearch(w, r)
+	case r.Method == http.MethodPost && r.URL.Path == "/jobs":
+		s.handleCreateJob(w, r)
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/audio"):
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/jobs/"), "/audio")
+		s.handleGetJobAudio(w, r, id)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/jobs/"):
+		id := strings.TrimPrefix(r.URL.Path, "/jobs/")
+		s.handleGetJob(w, r, id)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// runServer starts the HTTP API on addr, backed by a SQLite job store at
+// dbPath.
+func runServer(addr, dbPath string) error {
+	store, err := NewJobStore(dbPath)
+	if err != nil {
+		return fmt.Errorf("opening job store: %w", err)
+	}
+	defer store.Close()
+
+	server := NewServer(store, 4)
+
+	pending, err := store.ListNonTerminal()
+	if err != nil {
+		return fmt.Errorf("listing non-terminal jobs: %w", err)
+	}
+	for _, job := range pending {
+		log.Println("requeueing job", job.ID, "left", job.Status, "at restart")
+		server.jobs <- job
+	}
+
+	log.Println("listening on", addr)
+	return http.ListenAndServe(addr, server)
+}
+
+// resolveJobFormat fetches videoID's info and the Format matching formatItag.
+func resolveJobFormat(videoID string, formatItag int) (*ytdl.VideoInfo, *ytdl.Format, error) {
+	vid, err := ytdl.DefaultClient.GetVideoInfoFromID(context.Background(), videoID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching video info: %w", err)
+	}
+
+	for _, format := range vid.Formats {
+		if format.Itag.Number == formatItag {
+			return vid, format, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("format itag %d not found for video %s", formatItag, videoID)
+}
+
+// downloadForJob streams the selected format through the same in-process
+// Downloader the interactive CLI uses, to a local file, and returns its path.
+func downloadForJob(videoID string, formatItag int) (string, error) {
+	vid, selectedFormat, err := resolveJobFormat(videoID, formatItag)
+	if err != nil {
+		return "", err
+	}
+
+	fullFileName := sanitize.BaseName(vid.Title) + "." + selectedFormat.Extension
+	file, err := os.Create(fullFileName)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if err := NewDownloader().Download(context.Background(), videoID, formatItag, selectedFormat.Extension, file, nil); err != nil {
+		return "", err
+	}
+	return fullFileName, nil
+}
+
+// uploadJobToS3 streams the selected format straight into S3 under
+// "<jobID>/<video title>.<ext>", without ever writing it to local disk, and
+// returns the resulting object key.
+func uploadJobToS3(ctx context.Context, jobID, videoID string, formatItag int) (string, error) {
+	vid, selectedFormat, err := resolveJobFormat(videoID, formatItag)
+	if err != nil {
+		return "", err
+	}
+	key := jobID + "/" + sanitize.BaseName(vid.Title) + "." + selectedFormat.Extension
+
+	pr, pw := io.Pipe()
+	downloadErr := make(chan error, 1)
+	go func() {
+		err := NewDownloader().Download(ctx, videoID, formatItag, selectedFormat.Extension, pw, nil)
+		pw.CloseWithError(err)
+		downloadErr <- err
+	}()
+
+	if _, err := uploadToS3(ctx, key, pr); err != nil {
+		<-downloadErr
+		return "", err
+	}
+	if err := <-downloadErr; err != nil {
+		return "", err
+	}
+	return key, nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bogem/id3v2"
+	"golang.org/x/image/draw"
+)
+
+// TrackMetadata is the information pulled from the YouTube Data API that
+// gets embedded into a downloaded audio file.
+type TrackMetadata struct {
+	VideoID       string
+	Title         string
+	ChannelTitle  string
+	PlaylistTitle string
+	PublishedAt   time.Time
+	Description   string
+}
+
+func (m TrackMetadata) videoURL() string {
+	return "https://www.youtube.com/watch?v=" + m.VideoID
+}
+
+// tagOutput embeds m into the audio file at path, using ID3v2 for mp3 and
+// Vorbis comments for webm/ogg, matching tagFormat ("id3" or "vorbis").
+func tagOutput(path, tagFormat string, m TrackMetadata) error {
+	if tagFormat == "vorbis" {
+		return tagVorbis(path, m)
+	}
+	return tagMP3(path, m)
+}
+
+// tagMP3 writes ID3v2 frames to path, including a 500x500 APIC thumbnail
+// fetched from YouTube, using the pure-Go bogem/id3v2 tagger so no external
+// tool is required.
+func tagMP3(path string, m TrackMetadata) error {
+	tag, err := id3v2.Open(path, id3v2.Options{Parse: false})
+	if err != nil {
+		return fmt.Errorf("opening %s for tagging: %w", path, err)
+	}
+	defer tag.Close()
+
+	tag.SetTitle(m.Title)
+	tag.SetArtist(m.ChannelTitle)
+	if m.PlaylistTitle != "" {
+		tag.SetAlbum(m.PlaylistTitle)
+	}
+	if !m.PublishedAt.IsZero() {
+		tag.SetYear(strconv.Itoa(m.PublishedAt.Year()))
+	}
+	tag.AddCommentFrame(id3v2.CommentFrame{
+		Encoding:    id3v2.EncodingUTF8,
+		Language:    "eng",
+		Description: "",
+		Text:        m.Description,
+	})
+	tag.AddFrame("WOAS", id3v2.UnknownFrame{Body: []byte(m.videoURL())})
+
+	thumbnail, err := fetchThumbnail(m.VideoID)
+	if err != nil {
+		return fmt.Errorf("fetching thumbnail: %w", err)
+	}
+	if thumbnail != nil {
+		tag.AddAttachedPicture(id3v2.PictureFrame{
+			Encoding:    id3v2.EncodingUTF8,
+			MimeType:    "image/jpeg",
+			PictureType: id3v2.PTFrontCover,
+			Description: "Cover",
+			Picture:     thumbnail,
+		})
+	}
+
+	return tag.Save()
+}
+
+// tagVorbis remuxes path in place with Vorbis comment metadata via ffmpeg,
+// since writing webm/ogg containers directly isn't worth the complexity the
+// pure-Go mp3 tagger buys us for ID3.
+func tagVorbis(path string, m TrackMetadata) error {
+	tmpPath := path + ".tagged" + extensionOf(path)
+	args := []string{
+		"-i", path,
+		"-c", "copy",
+		"-metadata", "title=" + m.Title,
+		"-metadata", "artist=" + m.ChannelTitle,
+		"-metadata", "comment=" + m.Description,
+	}
+	if m.PlaylistTitle != "" {
+		args = append(args, "-metadata", "album="+m.PlaylistTitle)
+	}
+	args = append(args, tmpPath)
+
+	cmd := exec.Command("ffmpeg", args...)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("writing vorbis comments: %w", err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func extensionOf(path string) string {
+	if i := strings.LastIndex(path, "."); i != -1 {
+		return path[i:]
+	}
+	return ""
+}
+
+// fetchThumbnail downloads the video's highest-resolution thumbnail,
+// falling back from maxresdefault to hqdefault, and resizes it to 500x500.
+func fetchThumbnail(videoID string) ([]byte, error) {
+	for _, name := range []string{"maxresdefault", "hqdefault"} {
+		resp, err := http.Get("https://i.ytimg.com/vi/" + videoID + "/" + name + ".jpg")
+		if err != nil {
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			continue
+		}
+		img, err := jpeg.Decode(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			continue
+		}
+		return resizeJPEG(img, 500, 500)
+	}
+	return nil, nil
+}
+
+func resizeJPEG(src image.Image, width, height int) ([]byte, error) {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}